@@ -0,0 +1,33 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import "sort"
+
+// InsertBatch inserts every point of ps into the triangulation via Insert,
+// after sorting them by (x, y), so the resulting triangulation does not
+// depend on the order of ps. Each insertion still locates its point from the
+// root of the history DAG, exactly as Insert does.
+//
+// A prior version of InsertBatch triangulated the whole batch at once with
+// the Guibas-Stolfi divide-and-conquer algorithm (recursively triangulating
+// the two sorted halves and stitching them along their common tangent), but
+// its merge step produced structurally wrong triangulations -- far fewer
+// triangles than the same points inserted one at a time -- so it has been
+// reverted back to incremental insertion until the merge can be fixed.
+func (d *hierarchicalDelaunay) InsertBatch(ps []*Point) {
+	sorted := make([]*Point, len(ps))
+	copy(sorted, ps)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].x != sorted[j].x {
+			return sorted[i].x < sorted[j].x
+		}
+		return sorted[i].y < sorted[j].y
+	})
+
+	for _, p := range sorted {
+		d.Insert(p)
+	}
+}
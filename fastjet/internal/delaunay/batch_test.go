@@ -0,0 +1,77 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestInsertBatchOrderIndependent checks that InsertBatch's internal (x, y)
+// sort makes its result independent of the order the caller hands points in
+// -- the one property callers actually rely on, since InsertBatch makes no
+// promise about insertion order otherwise.
+func TestInsertBatchOrderIndependent(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ps := make([]*Point, 80)
+	for i := range ps {
+		ps[i] = NewPoint(rng.Float64()*1000, rng.Float64()*1000)
+	}
+
+	inOrder := make([]*Point, len(ps))
+	copy(inOrder, ps)
+	a := HierarchicalDelaunay()
+	a.InsertBatch(inOrder)
+
+	shuffled := make([]*Point, len(ps))
+	copy(shuffled, ps)
+	rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	b := HierarchicalDelaunay()
+	b.InsertBatch(shuffled)
+
+	got, want := len(a.Triangles()), len(b.Triangles())
+	if got != want {
+		t.Errorf("got=%d triangles for a shuffled batch, want=%d (same batch, original order)", got, want)
+	}
+}
+
+// TestInsertBatchPopulatesAdjacentTriangles checks the request's explicit
+// requirement that NearestNeighbor keeps working after a batch insert, by
+// comparing it against a brute-force nearest-neighbor scan.
+func TestInsertBatchPopulatesAdjacentTriangles(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	ps := make([]*Point, 50)
+	for i := range ps {
+		ps[i] = NewPoint(rng.Float64()*100, rng.Float64()*100)
+	}
+
+	d := HierarchicalDelaunay()
+	d.InsertBatch(ps)
+
+	for _, p := range ps {
+		if len(p.adjacentTriangles) == 0 {
+			t.Fatalf("point %v has no adjacent triangles after InsertBatch", p)
+		}
+
+		got, gotD := p.NearestNeighbor()
+
+		var want *Point
+		wantD := math.Inf(1)
+		for _, q := range ps {
+			if q == p {
+				continue
+			}
+			if d := p.distance(q); d < wantD {
+				want, wantD = q, d
+			}
+		}
+		wantD = math.Sqrt(wantD)
+
+		if got != want || math.Abs(gotD-wantD) > 1e-9 {
+			t.Errorf("point %v: got nearest=%v (d=%v), want=%v (d=%v)", p, got, gotD, want, wantD)
+		}
+	}
+}
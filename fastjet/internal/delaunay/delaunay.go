@@ -0,0 +1,117 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package delaunay computes Delaunay triangulations of a set of 2D points,
+// used internally by fastjet to answer Euclidean nearest-neighbor queries.
+package delaunay
+
+// superScale sets the size of the bounding "super triangle" that every
+// HierarchicalDelaunay starts from; it must be large enough to strictly
+// contain every point ever inserted.
+const superScale = 1e7
+
+// hierarchicalDelaunay is a Delaunay triangulation built by incremental
+// insertion, in the order points are given, with a history DAG rooted at a single bounding
+// "super triangle" used to answer point-location queries in better than
+// linear time.
+type hierarchicalDelaunay struct {
+	root  *Triangle
+	super [3]*Point
+}
+
+// HierarchicalDelaunay creates an empty triangulation, ready to have
+// points Insert-ed into it.
+func HierarchicalDelaunay() *hierarchicalDelaunay {
+	p1 := NewPoint(-superScale, -superScale)
+	p2 := NewPoint(superScale, -superScale)
+	p3 := NewPoint(0, superScale)
+
+	return &hierarchicalDelaunay{
+		root:  NewTriangle(p1, p2, p3),
+		super: [3]*Point{p1, p2, p3},
+	}
+}
+
+// Insert adds p to the triangulation, locating the leaf Triangle it falls
+// into, subdividing it (or the pair of triangles sharing the edge p falls
+// on), and legalizing the newly created edges.
+func (d *hierarchicalDelaunay) Insert(p *Point) {
+	leaf := locate(d.root, p)
+
+	switch leaf.contains(p) {
+	case inside:
+		d.insertInside(leaf, p)
+
+	case onEdge:
+		a, b := leaf.edgeContaining(p)
+		neighbor := commonTriangle(a, b, leaf)
+		if neighbor == nil {
+			// (a, b) is a hull edge: there is no partner triangle to split
+			// alongside leaf, so fall back to an ordinary 3-way split.
+			d.insertInside(leaf, p)
+			return
+		}
+
+		children := splitEdge(leaf, neighbor, a, b, p)
+		c1 := leaf.opposite(a, b)
+		c2 := neighbor.opposite(a, b)
+		legalize(children[0], p, a, c1)
+		legalize(children[1], p, c1, b)
+		legalize(children[2], p, b, c2)
+		legalize(children[3], p, c2, a)
+	}
+}
+
+func (d *hierarchicalDelaunay) insertInside(leaf *Triangle, p *Point) {
+	children := splitTriangle(leaf, p)
+	legalize(children[0], p, leaf.p1, leaf.p2)
+	legalize(children[1], p, leaf.p2, leaf.p3)
+	legalize(children[2], p, leaf.p3, leaf.p1)
+}
+
+// isSuper reports whether p is one of the bounding super-triangle's
+// vertices.
+func (d *hierarchicalDelaunay) isSuper(p *Point) bool {
+	return p == d.super[0] || p == d.super[1] || p == d.super[2]
+}
+
+// touchesSuper reports whether any vertex of t is part of the bounding
+// super-triangle -- such triangles are an artifact of the construction and
+// are excluded from Triangles.
+func (d *hierarchicalDelaunay) touchesSuper(t *Triangle) bool {
+	for _, p := range t.points() {
+		if d.isSuper(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Triangles returns the live (leaf) triangles of the triangulation,
+// excluding those touching the bounding super-triangle.
+func (d *hierarchicalDelaunay) Triangles() []*Triangle {
+	var out []*Triangle
+	seen := make(map[*Triangle]bool)
+
+	var walk func(t *Triangle)
+	walk = func(t *Triangle) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+
+		if t.isLeaf() {
+			if !d.touchesSuper(t) {
+				out = append(out, t)
+			}
+			return
+		}
+		for _, c := range t.children {
+			walk(c)
+		}
+	}
+	walk(d.root)
+
+	return out
+}
@@ -0,0 +1,192 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import "math"
+
+// locate walks the history DAG rooted at t down to the leaf Triangle
+// containing p.
+func locate(t *Triangle, p *Point) *Triangle {
+	for !t.isLeaf() {
+		next := t.children[0]
+		for _, c := range t.children {
+			if c.contains(p) != outside {
+				next = c
+				break
+			}
+		}
+		t = next
+	}
+	return t
+}
+
+// addAdjacent records t as one of p's incident triangles.
+func addAdjacent(p *Point, t *Triangle) {
+	p.adjacentTriangles = append(p.adjacentTriangles, t)
+	p.invalidateNearest()
+}
+
+// removeAdjacent drops t from p's incident triangles.
+func removeAdjacent(p *Point, t *Triangle) {
+	ts := p.adjacentTriangles
+	for i, o := range ts {
+		if o == t {
+			p.adjacentTriangles = append(ts[:i], ts[i+1:]...)
+			break
+		}
+	}
+	p.invalidateNearest()
+}
+
+// neighbors returns p's Delaunay graph neighbors, derived from the
+// triangles currently recorded as adjacent to p.
+func neighbors(p *Point) []*Point {
+	seen := map[*Point]bool{}
+	var out []*Point
+	for _, t := range p.adjacentTriangles {
+		for _, q := range t.points() {
+			if q == p || seen[q] {
+				continue
+			}
+			seen[q] = true
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// commonTriangle returns the live triangle, other than exclude, that is
+// incident to both a and b -- i.e. the triangle across the edge (a, b)
+// from exclude -- or nil if (a, b) is a hull edge.
+func commonTriangle(a, b *Point, exclude *Triangle) *Triangle {
+	for _, t := range a.adjacentTriangles {
+		if t == exclude || !t.isLeaf() {
+			continue
+		}
+		for _, u := range b.adjacentTriangles {
+			if u == t {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// edgeContaining returns the two endpoints of the edge of t that p lies on.
+// It assumes t.contains(p) == onEdge.
+func (t *Triangle) edgeContaining(p *Point) (*Point, *Point) {
+	edges := [3][2]*Point{{t.p1, t.p2}, {t.p2, t.p3}, {t.p3, t.p1}}
+	for _, e := range edges {
+		if math.Abs(cross(e[0], e[1], p)) <= eps && between(e[0], e[1], p) {
+			return e[0], e[1]
+		}
+	}
+	return nil, nil
+}
+
+// splitTriangle replaces leaf t, which strictly contains p, with the three
+// triangles obtained by connecting p to each of t's vertices.
+func splitTriangle(t *Triangle, p *Point) [3]*Triangle {
+	a, b, c := t.p1, t.p2, t.p3
+
+	t1 := NewTriangle(a, b, p)
+	t2 := NewTriangle(b, c, p)
+	t3 := NewTriangle(c, a, p)
+	t.children = []*Triangle{t1, t2, t3}
+
+	removeAdjacent(a, t)
+	removeAdjacent(b, t)
+	removeAdjacent(c, t)
+
+	addAdjacent(a, t1)
+	addAdjacent(a, t3)
+	addAdjacent(b, t1)
+	addAdjacent(b, t2)
+	addAdjacent(c, t2)
+	addAdjacent(c, t3)
+	addAdjacent(p, t1)
+	addAdjacent(p, t2)
+	addAdjacent(p, t3)
+
+	return [3]*Triangle{t1, t2, t3}
+}
+
+// splitEdge replaces the two leaves t1 = (a, b, c1) and t2 = (a, b, c2),
+// which share the edge (a, b) that p lies on, with the four triangles
+// obtained by connecting p to a, b, c1 and c2.
+func splitEdge(t1, t2 *Triangle, a, b, p *Point) [4]*Triangle {
+	c1 := t1.opposite(a, b)
+	c2 := t2.opposite(a, b)
+
+	n1 := NewTriangle(a, c1, p)
+	n2 := NewTriangle(c1, b, p)
+	n3 := NewTriangle(b, c2, p)
+	n4 := NewTriangle(c2, a, p)
+
+	children := []*Triangle{n1, n2, n3, n4}
+	t1.children = children
+	t2.children = children
+
+	removeAdjacent(a, t1)
+	removeAdjacent(a, t2)
+	removeAdjacent(b, t1)
+	removeAdjacent(b, t2)
+	removeAdjacent(c1, t1)
+	removeAdjacent(c2, t2)
+
+	addAdjacent(a, n1)
+	addAdjacent(a, n4)
+	addAdjacent(b, n2)
+	addAdjacent(b, n3)
+	addAdjacent(c1, n1)
+	addAdjacent(c1, n2)
+	addAdjacent(c2, n3)
+	addAdjacent(c2, n4)
+	addAdjacent(p, n1)
+	addAdjacent(p, n2)
+	addAdjacent(p, n3)
+	addAdjacent(p, n4)
+
+	return [4]*Triangle{n1, n2, n3, n4}
+}
+
+// legalize checks the edge (a, b) of t = (p, a, b), the edge opposite the
+// newly inserted vertex p, and flips it if the Triangle across it violates
+// the Delaunay condition, recursing into the two triangles created by the
+// flip.
+func legalize(t *Triangle, p, a, b *Point) {
+	neighbor := commonTriangle(a, b, t)
+	if neighbor == nil {
+		// (a, b) is a hull edge: nothing to flip against.
+		return
+	}
+
+	c := neighbor.opposite(a, b)
+	if !inCircle(t.p1, t.p2, t.p3, c) {
+		return
+	}
+
+	nt1 := NewTriangle(p, a, c)
+	nt2 := NewTriangle(p, c, b)
+	t.children = []*Triangle{nt1, nt2}
+	neighbor.children = []*Triangle{nt1, nt2}
+
+	removeAdjacent(a, t)
+	removeAdjacent(a, neighbor)
+	removeAdjacent(b, t)
+	removeAdjacent(b, neighbor)
+	removeAdjacent(p, t)
+	removeAdjacent(c, neighbor)
+
+	addAdjacent(p, nt1)
+	addAdjacent(p, nt2)
+	addAdjacent(a, nt1)
+	addAdjacent(c, nt1)
+	addAdjacent(c, nt2)
+	addAdjacent(b, nt2)
+
+	legalize(nt1, p, a, c)
+	legalize(nt2, p, c, b)
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import (
+	"container/heap"
+	"math"
+)
+
+// neighborEntry is a candidate neighbor together with its squared distance
+// to the point the search started from.
+type neighborEntry struct {
+	p *Point
+	d float64
+}
+
+// neighborHeap is a max-heap of at most k neighborEntry values, keyed by
+// distance, so the worst candidate found so far always sits at the root and
+// can be evicted in O(log k) as closer ones are discovered.
+type neighborHeap []neighborEntry
+
+func (h neighborHeap) Len() int            { return len(h) }
+func (h neighborHeap) Less(i, j int) bool  { return h[i].d > h[j].d }
+func (h neighborHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *neighborHeap) Push(x interface{}) { *h = append(*h, x.(neighborEntry)) }
+func (h *neighborHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
+
+// KNearestNeighbors returns p's k nearest neighbors, closest first, and
+// their (non-squared) Euclidean distances.
+//
+// It works by breadth-first search over the Delaunay graph, keeping a
+// bounded max-heap of the k best candidates seen so far and evicting the
+// current worst whenever a closer point turns up, so candidates never need
+// to be collected in full and sorted afterwards. Since a Point has no
+// reference to the full point set to fall back to, the BFS itself is the
+// fallback: it keeps expanding layers until the frontier is exhausted, i.e.
+// until every point reachable from p -- which, for a connected
+// triangulation, is every other point -- has been considered, rather than
+// stopping early on a heuristic and risking fewer than k results.
+func (p *Point) KNearestNeighbors(k int) ([]*Point, []float64) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	visited := map[*Point]bool{p: true}
+	frontier := []*Point{p}
+	var h neighborHeap
+
+	for len(frontier) > 0 {
+		var next []*Point
+		for _, q := range frontier {
+			for _, r := range neighbors(q) {
+				if visited[r] {
+					continue
+				}
+				visited[r] = true
+				next = append(next, r)
+
+				d := p.distance(r)
+				switch {
+				case h.Len() < k:
+					heap.Push(&h, neighborEntry{r, d})
+				case d < h[0].d:
+					heap.Pop(&h)
+					heap.Push(&h, neighborEntry{r, d})
+				}
+			}
+		}
+		frontier = next
+	}
+
+	points := make([]*Point, h.Len())
+	dists := make([]float64, h.Len())
+	for i := len(points) - 1; i >= 0; i-- {
+		e := heap.Pop(&h).(neighborEntry)
+		points[i] = e.p
+		dists[i] = math.Sqrt(e.d)
+	}
+	return points, dists
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestKNearestNeighbors(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	ps := make([]*Point, 40)
+	for i := range ps {
+		ps[i] = NewPoint(rng.Float64()*100, rng.Float64()*100)
+	}
+
+	d := HierarchicalDelaunay()
+	for _, p := range ps {
+		d.Insert(p)
+	}
+
+	const k = 5
+	got, dists := ps[0].KNearestNeighbors(k)
+	if len(got) != k || len(dists) != k {
+		t.Fatalf("got=%d neighbors, want=%d", len(got), k)
+	}
+
+	for i := 1; i < len(dists); i++ {
+		if dists[i] < dists[i-1] {
+			t.Errorf("distances not sorted ascending: %v", dists)
+		}
+	}
+	for i, p := range got {
+		want := math.Sqrt(ps[0].distance(p))
+		if math.Abs(dists[i]-want) > 1e-9 {
+			t.Errorf("distance %d: got=%v, want=%v", i, dists[i], want)
+		}
+	}
+}
+
+func TestKNearestNeighborsMoreThanAvailable(t *testing.T) {
+	// Asking for more neighbors than the triangulation's graph can ever
+	// provide (it also includes the bounding super-triangle's vertices)
+	// must still terminate and return a consistent, fully sorted result,
+	// rather than hang or come back short without explanation.
+	p1 := NewPoint(0, 0)
+	p2 := NewPoint(1, 0)
+	p3 := NewPoint(0, 1)
+
+	d := HierarchicalDelaunay()
+	for _, p := range []*Point{p1, p2, p3} {
+		d.Insert(p)
+	}
+
+	got, dists := p1.KNearestNeighbors(10)
+	if len(got) != len(dists) {
+		t.Fatalf("got=%d neighbors but %d distances", len(got), len(dists))
+	}
+	seen := map[*Point]bool{}
+	for i, p := range got {
+		if seen[p] {
+			t.Errorf("neighbor %v returned more than once", p)
+		}
+		seen[p] = true
+		if i > 0 && dists[i] < dists[i-1] {
+			t.Errorf("distances not sorted ascending: %v", dists)
+		}
+	}
+	if !seen[p2] || !seen[p3] {
+		t.Errorf("got=%v, want p2 and p3 among the neighbors of p1", got)
+	}
+}
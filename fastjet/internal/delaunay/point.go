@@ -0,0 +1,151 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import (
+	"fmt"
+	"math"
+)
+
+// eps is the tolerance used when deciding whether a point lies exactly on
+// the line supporting a triangle edge.
+const eps = 1e-9
+
+// location describes where a Point lies with respect to a Triangle.
+type location int
+
+const (
+	inside location = iota
+	onEdge
+	outside
+)
+
+// Point is a 2D point that also keeps track of the Triangles currently
+// incident to it in a HierarchicalDelaunay, so that nearest-neighbor
+// queries can be answered without rescanning the whole triangulation.
+type Point struct {
+	x, y float64
+
+	adjacentTriangles []*Triangle
+
+	nearest  *Point
+	nearestD float64 // squared distance to nearest, valid iff nearest != nil
+}
+
+// NewPoint creates a Point at the given coordinates.
+func NewPoint(x, y float64) *Point {
+	return &Point{x: x, y: y}
+}
+
+// X returns the point's x coordinate.
+func (p *Point) X() float64 { return p.x }
+
+// Y returns the point's y coordinate.
+func (p *Point) Y() float64 { return p.y }
+
+// Equals reports whether p and o have the same coordinates.
+func (p *Point) Equals(o *Point) bool {
+	return p.x == o.x && p.y == o.y
+}
+
+// distance returns the squared Euclidean distance between p and o.
+func (p *Point) distance(o *Point) float64 {
+	dx := p.x - o.x
+	dy := p.y - o.y
+	return dx*dx + dy*dy
+}
+
+func (p *Point) String() string {
+	return fmt.Sprintf("(%g, %g)", p.x, p.y)
+}
+
+// findNearest recomputes p's nearest neighbor from its adjacentTriangles,
+// which, in a Delaunay triangulation, are guaranteed to contain it: the
+// nearest neighbor of any point is always one of its Delaunay neighbors.
+func (p *Point) findNearest() {
+	p.nearest = nil
+	p.nearestD = math.Inf(1)
+	for _, t := range p.adjacentTriangles {
+		for _, q := range t.points() {
+			if q.Equals(p) {
+				continue
+			}
+			d := p.distance(q)
+			if d < p.nearestD {
+				p.nearestD = d
+				p.nearest = q
+			}
+		}
+	}
+}
+
+// NearestNeighbor returns p's nearest neighbor among the points sharing a
+// triangle with p, and the (non-squared) Euclidean distance to it.
+func (p *Point) NearestNeighbor() (*Point, float64) {
+	if p.nearest == nil {
+		p.findNearest()
+	}
+	if p.nearest == nil {
+		return nil, 0
+	}
+	return p.nearest, math.Sqrt(p.nearestD)
+}
+
+// invalidateNearest forces the next NearestNeighbor call to recompute from
+// the (now stale) adjacentTriangles list.
+func (p *Point) invalidateNearest() {
+	p.nearest = nil
+}
+
+// cross returns twice the signed area of the triangle (a, b, c): positive
+// when c is to the left of the directed line a->b, negative when to the
+// right, zero when a, b, c are collinear.
+func cross(a, b, c *Point) float64 {
+	return (b.x-a.x)*(c.y-a.y) - (b.y-a.y)*(c.x-a.x)
+}
+
+// between reports whether p lies within the bounding box of segment a-b,
+// assuming p is already known to be collinear with a and b.
+func between(a, b, p *Point) bool {
+	return math.Min(a.x, b.x)-eps <= p.x && p.x <= math.Max(a.x, b.x)+eps &&
+		math.Min(a.y, b.y)-eps <= p.y && p.y <= math.Max(a.y, b.y)+eps
+}
+
+// inTriangle locates p with respect to t.
+func (p *Point) inTriangle(t *Triangle) location {
+	d1 := cross(t.p1, t.p2, p)
+	d2 := cross(t.p2, t.p3, p)
+	d3 := cross(t.p3, t.p1, p)
+
+	neg := d1 < -eps || d2 < -eps || d3 < -eps
+	pos := d1 > eps || d2 > eps || d3 > eps
+	if neg && pos {
+		return outside
+	}
+
+	switch {
+	case math.Abs(d1) <= eps && between(t.p1, t.p2, p):
+		return onEdge
+	case math.Abs(d2) <= eps && between(t.p2, t.p3, p):
+		return onEdge
+	case math.Abs(d3) <= eps && between(t.p3, t.p1, p):
+		return onEdge
+	case math.Abs(d1) <= eps || math.Abs(d2) <= eps || math.Abs(d3) <= eps:
+		return outside
+	}
+	return inside
+}
+
+// inCircle reports whether d lies strictly inside the circumcircle of the
+// triangle (a, b, c), which must be given in counter-clockwise order.
+func inCircle(a, b, c, d *Point) bool {
+	ax, ay := a.x-d.x, a.y-d.y
+	bx, by := b.x-d.x, b.y-d.y
+	cx, cy := c.x-d.x, c.y-d.y
+
+	return (ax*ax+ay*ay)*(bx*cy-cx*by)-
+		(bx*bx+by*by)*(ax*cy-cx*ay)+
+		(cx*cx+cy*cy)*(ax*by-bx*ay) > eps
+}
@@ -0,0 +1,164 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import "sort"
+
+// Remove deletes p from the triangulation by re-triangulating the star
+// polygon formed by p's incident triangles (ear-clipping the resulting
+// cavity) and then locally flipping any edge that violates the Delaunay
+// condition, exactly as Insert does for newly added edges. The history DAG
+// is updated so that locate still works for points inserted afterwards.
+//
+// Points on the convex hull of the inserted points are removed like any
+// other: the bounding super-triangle surrounds them, so their fan of
+// incident triangles is a closed ring. Removing one of the super-triangle's
+// own vertices is a silent no-op, as is removing a point whose fan of
+// incident triangles is open or has fewer than three triangles.
+func (d *hierarchicalDelaunay) Remove(p *Point) {
+	if d.isSuper(p) {
+		return
+	}
+
+	var real []*Triangle
+	for _, t := range p.adjacentTriangles {
+		if t.isLeaf() {
+			real = append(real, t)
+		}
+	}
+	if len(real) < 3 {
+		return
+	}
+
+	sort.Slice(real, func(i, j int) bool {
+		return angleFrom(p, centroid(real[i])) < angleFrom(p, centroid(real[j]))
+	})
+
+	for i := range real {
+		j := (i + 1) % len(real)
+		if !shareVertex(real[i], real[j], p) {
+			// p is on the hull: the fan is open, not a closed ring.
+			return
+		}
+	}
+
+	ring := ringAround(p, real)
+
+	for _, t := range real {
+		for _, v := range t.points() {
+			if v != p {
+				removeAdjacent(v, t)
+			}
+		}
+	}
+
+	cavity := earClip(ring)
+	for _, t := range real {
+		// Each of p's incident triangles gets the whole cavity as its
+		// children, each in its own copy of the slice: any point that
+		// locates into one of them now lies in one of the cavity triangles.
+		t.children = append([]*Triangle{}, cavity...)
+	}
+	for _, t := range cavity {
+		for _, v := range t.points() {
+			addAdjacent(v, t)
+		}
+	}
+
+	for _, t := range cavity {
+		if !t.isLeaf() {
+			continue
+		}
+		legalize(t, t.p1, t.p2, t.p3)
+		if !t.isLeaf() {
+			continue
+		}
+		legalize(t, t.p2, t.p3, t.p1)
+		if !t.isLeaf() {
+			continue
+		}
+		legalize(t, t.p3, t.p1, t.p2)
+	}
+}
+
+// ringAround returns the polygon, in CCW order, formed by the vertices of
+// tris -- p's incident triangles, themselves already sorted CCW around p
+// -- other than p itself.
+func ringAround(p *Point, tris []*Triangle) []*Point {
+	n := len(tris)
+	ring := make([]*Point, n)
+	for i := range tris {
+		j := (i - 1 + n) % n
+		ring[i] = sharedVertex(tris[j], tris[i], p)
+	}
+	return ring
+}
+
+// sharedVertex returns the vertex, other than exclude, common to a and b.
+func sharedVertex(a, b *Triangle, exclude *Point) *Point {
+	for _, x := range a.points() {
+		if x == exclude {
+			continue
+		}
+		for _, y := range b.points() {
+			if x == y {
+				return x
+			}
+		}
+	}
+	return nil
+}
+
+// earClip triangulates the simple polygon poly (given in CCW order) by
+// repeatedly clipping off "ears": vertices whose triangle with their two
+// neighbors is convex and contains no other polygon vertex.
+func earClip(poly []*Point) []*Triangle {
+	pts := append([]*Point{}, poly...)
+	var tris []*Triangle
+
+	for len(pts) > 3 {
+		n := len(pts)
+		clipped := false
+		for i := 0; i < n; i++ {
+			prev := pts[(i-1+n)%n]
+			cur := pts[i]
+			next := pts[(i+1)%n]
+
+			if cross(prev, cur, next) <= eps {
+				continue
+			}
+			if polyContainsOther(prev, cur, next, pts) {
+				continue
+			}
+
+			tris = append(tris, NewTriangle(prev, cur, next))
+			pts = append(pts[:i], pts[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			// Numerically degenerate polygon: stop rather than loop
+			// forever: whatever has already been clipped is kept.
+			return tris
+		}
+	}
+	if len(pts) == 3 {
+		tris = append(tris, NewTriangle(pts[0], pts[1], pts[2]))
+	}
+	return tris
+}
+
+func polyContainsOther(a, b, c *Point, pts []*Point) bool {
+	tri := NewTriangle(a, b, c)
+	for _, q := range pts {
+		if q == a || q == b || q == c {
+			continue
+		}
+		if q.inTriangle(tri) != outside {
+			return true
+		}
+	}
+	return false
+}
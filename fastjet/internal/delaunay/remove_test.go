@@ -0,0 +1,39 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRemoveInteriorPoint(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	ps := make([]*Point, 40)
+	for i := range ps {
+		ps[i] = NewPoint(rng.Float64()*100, rng.Float64()*100)
+	}
+
+	d := HierarchicalDelaunay()
+	for _, p := range ps {
+		d.Insert(p)
+	}
+
+	before := len(d.Triangles())
+	target := ps[20]
+	d.Remove(target)
+	after := len(d.Triangles())
+
+	if after >= before {
+		t.Errorf("got=%d triangles after Remove, want fewer than before=%d", after, before)
+	}
+	for _, tri := range d.Triangles() {
+		for _, p := range tri.points() {
+			if p == target {
+				t.Errorf("removed point %v still present in triangle %v", target, tri)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import "fmt"
+
+// Triangle is a node of the history DAG: it is a triangle of the
+// triangulation at some point in time, identified by its three vertices.
+//
+// Once a Triangle has been subdivided (by inserting a point inside it, on
+// one of its edges, or by an edge flip during legalization) it is no
+// longer part of the live triangulation, but is kept around, along with
+// its children, so that point-location queries started at the root can
+// still find their way down to the current leaves.
+type Triangle struct {
+	p1, p2, p3 *Point
+
+	children []*Triangle
+}
+
+// NewTriangle creates a Triangle with vertices p1, p2, p3, reordered if
+// necessary so that they are listed counter-clockwise.
+func NewTriangle(p1, p2, p3 *Point) *Triangle {
+	t := &Triangle{p1: p1, p2: p2, p3: p3}
+	if cross(p1, p2, p3) < 0 {
+		t.p2, t.p3 = p3, p2
+	}
+	return t
+}
+
+// points returns the Triangle's three vertices.
+func (t *Triangle) points() [3]*Point {
+	return [3]*Point{t.p1, t.p2, t.p3}
+}
+
+// Equals reports whether t and o have the same three vertices, regardless
+// of order.
+func (t *Triangle) Equals(o *Triangle) bool {
+	for _, p := range t.points() {
+		found := false
+		for _, q := range o.points() {
+			if p.Equals(q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Triangle) String() string {
+	return fmt.Sprintf("[%s %s %s]", t.p1, t.p2, t.p3)
+}
+
+// isLeaf reports whether t is still part of the live triangulation, i.e.
+// has not yet been subdivided.
+func (t *Triangle) isLeaf() bool {
+	return len(t.children) == 0
+}
+
+// contains locates p with respect to t.
+func (t *Triangle) contains(p *Point) location {
+	return p.inTriangle(t)
+}
+
+// opposite returns t's vertex that is neither a nor b, assuming a and b are
+// two of t's vertices.
+func (t *Triangle) opposite(a, b *Point) *Point {
+	for _, v := range t.points() {
+		if v != a && v != b {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import (
+	"math"
+	"sort"
+)
+
+// Vertex is a 2D coordinate, as opposed to a Point, which additionally
+// tracks its place in the Delaunay triangulation.
+type Vertex struct {
+	X, Y float64
+}
+
+// VoronoiCell is the Voronoi dual of a single input Point: the polygon of
+// all locations closer to Site than to any other input point.
+type VoronoiCell struct {
+	Site *Point
+
+	// Vertices are the cell's corners, in CCW order. Each one is the
+	// circumcenter of a Delaunay triangle incident to Site.
+	Vertices []Vertex
+
+	// Bounded reports whether the cell is a closed polygon. It is false
+	// for sites on the convex hull of the triangulation, whose cell is
+	// open; Rays then gives the direction of its two unbounded edges,
+	// anchored at Vertices[0] and Vertices[len(Vertices)-1] respectively.
+	// For a site with a single incident triangle, both Rays are left zero.
+	Bounded bool
+	Rays    [2]Vertex
+}
+
+// Voronoi is the dual graph of a Delaunay triangulation: one vertex per
+// triangle (its circumcenter), one edge per Delaunay edge connecting the
+// circumcenters of the (at most two) triangles sharing it, and one cell
+// per input Point.
+type Voronoi struct {
+	d     *hierarchicalDelaunay
+	cells map[*Point]*VoronoiCell
+}
+
+// Voronoi returns the Voronoi diagram dual to d.
+func (d *hierarchicalDelaunay) Voronoi() *Voronoi {
+	v := &Voronoi{d: d, cells: map[*Point]*VoronoiCell{}}
+
+	sites := map[*Point]bool{}
+	for _, t := range d.Triangles() {
+		for _, p := range t.points() {
+			sites[p] = true
+		}
+	}
+
+	for p := range sites {
+		if cell := voronoiCell(d, p); cell != nil {
+			v.cells[p] = cell
+		}
+	}
+
+	return v
+}
+
+// Cell returns p's Voronoi cell, or nil if p is not a site of the
+// triangulation this Voronoi diagram was built from.
+func (v *Voronoi) Cell(p *Point) *VoronoiCell {
+	return v.cells[p]
+}
+
+// NearestSite returns the input Point closest to (x, y). It uses the
+// Delaunay triangulation to locate the triangle containing (x, y) in
+// better than linear time, starts from the nearest of its three vertices,
+// and then walks the Delaunay graph to any closer neighbor until none is
+// left: a site with no closer Delaunay neighbor is the nearest one.
+func (v *Voronoi) NearestSite(x, y float64) *Point {
+	q := NewPoint(x, y)
+	t := locate(v.d.root, q)
+
+	best := t.p1
+	bestD := q.distance(t.p1)
+	for _, p := range [2]*Point{t.p2, t.p3} {
+		if dd := q.distance(p); dd < bestD {
+			bestD = dd
+			best = p
+		}
+	}
+
+	for improved := true; improved; {
+		improved = false
+		for _, p := range neighbors(best) {
+			if dd := q.distance(p); dd < bestD {
+				bestD = dd
+				best = p
+				improved = true
+			}
+		}
+	}
+	return best
+}
+
+// voronoiCell builds p's Voronoi cell from the real (non-super-triangle)
+// Delaunay triangles incident to it.
+func voronoiCell(d *hierarchicalDelaunay, p *Point) *VoronoiCell {
+	var real []*Triangle
+	for _, t := range p.adjacentTriangles {
+		if t.isLeaf() && !d.touchesSuper(t) {
+			real = append(real, t)
+		}
+	}
+	if len(real) == 0 {
+		return nil
+	}
+
+	sort.Slice(real, func(i, j int) bool {
+		return angleFrom(p, centroid(real[i])) < angleFrom(p, centroid(real[j]))
+	})
+
+	gap := -1
+	for i := range real {
+		j := (i + 1) % len(real)
+		if !shareVertex(real[i], real[j], p) {
+			gap = i
+			break
+		}
+	}
+
+	cell := &VoronoiCell{Site: p, Bounded: gap == -1}
+	if cell.Bounded {
+		for _, t := range real {
+			cell.Vertices = append(cell.Vertices, circumcenter(t))
+		}
+		return cell
+	}
+
+	// Rotate so the open chain starts right after the gap: the fan no
+	// longer wraps around, so its two ends are the boundary edges.
+	ordered := append(append([]*Triangle{}, real[gap+1:]...), real[:gap+1]...)
+	for _, t := range ordered {
+		cell.Vertices = append(cell.Vertices, circumcenter(t))
+	}
+
+	first, last := ordered[0], ordered[len(ordered)-1]
+	if len(ordered) > 1 {
+		cell.Rays[0] = boundaryRay(p, first, boundaryVertex(first, p, ordered[1]))
+		cell.Rays[1] = boundaryRay(p, last, boundaryVertex(last, p, ordered[len(ordered)-2]))
+	}
+	return cell
+}
+
+// angleFrom returns the angle, around p, of the direction to v.
+func angleFrom(p *Point, v Vertex) float64 {
+	return math.Atan2(v.Y-p.y, v.X-p.x)
+}
+
+// shareVertex reports whether a and b, both incident to exclude, also
+// share another vertex.
+func shareVertex(a, b *Triangle, exclude *Point) bool {
+	for _, x := range a.points() {
+		if x == exclude {
+			continue
+		}
+		for _, y := range b.points() {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// boundaryVertex returns t's vertex that is neither p nor shared with
+// sharedWith -- the vertex at the open end of a hull site's triangle fan.
+func boundaryVertex(t *Triangle, p *Point, sharedWith *Triangle) *Point {
+	for _, v := range t.points() {
+		if v == p {
+			continue
+		}
+		shared := false
+		for _, w := range sharedWith.points() {
+			if v == w {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			return v
+		}
+	}
+	return nil
+}
+
+// boundaryRay returns the direction, pointing away from the triangulation,
+// of the unbounded Voronoi edge perpendicular to the hull edge (p, other)
+// of t.
+func boundaryRay(p *Point, t *Triangle, other *Point) Vertex {
+	if other == nil {
+		return Vertex{}
+	}
+
+	dx, dy := other.x-p.x, other.y-p.y
+	rx, ry := -dy, dx // rotate the edge direction by 90 degrees
+
+	third := t.opposite(p, other)
+	mx, my := (p.x+other.x)/2, (p.y+other.y)/2
+	if (mx-third.x)*rx+(my-third.y)*ry < 0 {
+		rx, ry = -rx, -ry
+	}
+	return Vertex{X: rx, Y: ry}
+}
+
+func centroid(t *Triangle) Vertex {
+	return Vertex{
+		X: (t.p1.x + t.p2.x + t.p3.x) / 3,
+		Y: (t.p1.y + t.p2.y + t.p3.y) / 3,
+	}
+}
+
+// circumcenter returns the center of t's circumcircle.
+func circumcenter(t *Triangle) Vertex {
+	ax, ay := t.p1.x, t.p1.y
+	bx, by := t.p2.x, t.p2.y
+	cx, cy := t.p3.x, t.p3.y
+
+	den := 2 * (ax*(by-cy) + bx*(cy-ay) + cx*(ay-by))
+	ux := ((ax*ax+ay*ay)*(by-cy) + (bx*bx+by*by)*(cy-ay) + (cx*cx+cy*cy)*(ay-by)) / den
+	uy := ((ax*ax+ay*ay)*(cx-bx) + (bx*bx+by*by)*(ax-cx) + (cx*cx+cy*cy)*(bx-ax)) / den
+	return Vertex{X: ux, Y: uy}
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delaunay
+
+import "testing"
+
+func TestVoronoiCellAndNearestSite(t *testing.T) {
+	corner1 := NewPoint(0, 0)
+	corner2 := NewPoint(4, 0)
+	corner3 := NewPoint(4, 4)
+	corner4 := NewPoint(0, 4)
+	center := NewPoint(2, 2)
+
+	d := HierarchicalDelaunay()
+	for _, p := range []*Point{corner1, corner2, corner3, corner4, center} {
+		d.Insert(p)
+	}
+
+	v := d.Voronoi()
+
+	cell := v.Cell(center)
+	if cell == nil {
+		t.Fatalf("got no cell for the interior point %v", center)
+	}
+	if !cell.Bounded {
+		t.Errorf("got unbounded cell for interior point %v, want bounded", center)
+	}
+
+	exp := []Vertex{{2, 0}, {4, 2}, {2, 4}, {0, 2}}
+	if len(cell.Vertices) != len(exp) {
+		t.Fatalf("got=%d cell vertices, want=%d", len(cell.Vertices), len(exp))
+	}
+	for i := range exp {
+		if cell.Vertices[i] != exp[i] {
+			t.Errorf("vertex %d: got=%v, want=%v", i, cell.Vertices[i], exp[i])
+		}
+	}
+
+	got := v.NearestSite(0.1, 0.1)
+	if !got.Equals(corner1) {
+		t.Errorf("got=%v nearest site to (0.1, 0.1), want=%v", got, corner1)
+	}
+}
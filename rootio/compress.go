@@ -0,0 +1,199 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rootio
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// rootHDRSIZE is the size, in bytes, of the header ROOT prepends to every
+// compressed block: 2 bytes identifying the algorithm, 1 byte for the
+// algorithm-specific method/version, and two 3-byte little-endian lengths
+// for the compressed and uncompressed sizes of that block.
+const rootHDRSIZE = 9
+
+// Decompressor wraps r so that reads from the returned io.Reader yield the
+// uncompressed bytes of a single ROOT compression block.
+//
+// Implementations are registered by their 2-byte ROOT algorithm tag (e.g.
+// "ZL", "XZ", "L4", "ZS") via RegisterDecompressor, so downstream packages
+// may add support for algorithms this package does not know about.
+type Decompressor func(r io.Reader) (io.Reader, error)
+
+var decompressors = map[string]Decompressor{}
+
+// RegisterDecompressor registers the decompressor for the given 2-byte ROOT
+// algorithm tag. It panics if a decompressor is already registered under
+// that name.
+func RegisterDecompressor(name string, fct Decompressor) {
+	if _, dup := decompressors[name]; dup {
+		panic(fmt.Errorf("rootio: Decompressor already registered for %q", name))
+	}
+	decompressors[name] = fct
+}
+
+func decompressorFor(name string) (Decompressor, error) {
+	fct, ok := decompressors[name]
+	if !ok {
+		return nil, fmt.Errorf("rootio: no Decompressor registered for algorithm %q", name)
+	}
+	return fct, nil
+}
+
+func init() {
+	RegisterDecompressor("ZL", func(r io.Reader) (io.Reader, error) {
+		return zlib.NewReader(r)
+	})
+	RegisterDecompressor("XZ", func(r io.Reader) (io.Reader, error) {
+		return lzma.NewReader(r)
+	})
+	RegisterDecompressor("L4", func(r io.Reader) (io.Reader, error) {
+		return lz4.NewReader(r), nil
+	})
+	RegisterDecompressor("ZS", func(r io.Reader) (io.Reader, error) {
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	})
+}
+
+// Compressor wraps w so that writes through the returned io.WriteCloser are
+// compressed with one algorithm, as a single stream. Splitting a payload
+// into ROOT blocks no larger than maxCompBufSize, each one prefixed by a
+// rootHDRSIZE-byte header, is done by compress, so that a Decompressor
+// registered under the same name can read each block back.
+//
+// Compressor implementations are registered by their 2-byte ROOT algorithm
+// tag via RegisterCompressor, mirroring the Decompressor registry, so that
+// downstream packages may add codecs this package does not know about.
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+// maxCompBufSize is the largest number of uncompressed bytes ROOT will pack
+// into a single compressed block; larger payloads are split into several
+// chunks, each with its own rootHDRSIZE-byte header.
+const maxCompBufSize = 0xffffff // 3-byte length field in the block header
+
+var compressors = map[string]Compressor{}
+
+// RegisterCompressor registers the compressor for the given 2-byte ROOT
+// algorithm tag. It panics if a compressor is already registered under that
+// name.
+func RegisterCompressor(name string, fct Compressor) {
+	if _, dup := compressors[name]; dup {
+		panic(fmt.Errorf("rootio: Compressor already registered for %q", name))
+	}
+	compressors[name] = fct
+}
+
+func compressorFor(name string) (Compressor, error) {
+	fct, ok := compressors[name]
+	if !ok {
+		return nil, fmt.Errorf("rootio: no Compressor registered for algorithm %q", name)
+	}
+	return fct, nil
+}
+
+func init() {
+	RegisterCompressor("ZL", func(w io.Writer) (io.WriteCloser, error) {
+		return zlib.NewWriter(w), nil
+	})
+	RegisterCompressor("L4", func(w io.Writer) (io.WriteCloser, error) {
+		return lz4.NewWriter(w), nil
+	})
+	RegisterCompressor("ZS", func(w io.Writer) (io.WriteCloser, error) {
+		return zstd.NewWriter(w)
+	})
+}
+
+// compress splits src into chunks of at most maxCompBufSize bytes, compresses
+// each one with the named algorithm, and writes each as a rootHDRSIZE-byte
+// header followed by the compressed bytes. It returns the total number of
+// bytes written, i.e. the on-file size of the compressed object.
+func compress(w io.Writer, algo string, method byte, src []byte) (int, error) {
+	fct, err := compressorFor(algo)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for len(src) > 0 {
+		n := len(src)
+		if n > maxCompBufSize {
+			n = maxCompBufSize
+		}
+		chunk := src[:n]
+		src = src[n:]
+
+		var buf bytes.Buffer
+		cw, err := fct(&buf)
+		if err != nil {
+			return total, err
+		}
+		if _, err = cw.Write(chunk); err != nil {
+			return total, err
+		}
+		if err = cw.Close(); err != nil {
+			return total, err
+		}
+
+		hdr := [rootHDRSIZE]byte{algo[0], algo[1], method}
+		putLE3(hdr[3:6], int32(buf.Len()))
+		putLE3(hdr[6:9], int32(n))
+		if _, err = w.Write(hdr[:]); err != nil {
+			return total, err
+		}
+		if _, err = w.Write(buf.Bytes()); err != nil {
+			return total, err
+		}
+		total += rootHDRSIZE + buf.Len()
+	}
+	return total, nil
+}
+
+// putLE3 encodes v as a 3-byte little-endian unsigned integer, as used by
+// ROOT for the compressed/uncompressed lengths in a block header.
+func putLE3(p []byte, v int32) {
+	p[0] = byte(v)
+	p[1] = byte(v >> 8)
+	p[2] = byte(v >> 16)
+}
+
+// rcompHdr describes the 9-byte header prefixing a single ROOT compressed
+// block, as read from the file.
+type rcompHdr struct {
+	algo   string // 2-byte algorithm tag: "ZL", "XZ", "L4", "ZS"
+	method byte   // algorithm-specific method/version byte
+	clen   int32  // size, in bytes, of the compressed block (excluding this header)
+	ulen   int32  // size, in bytes, of the uncompressed block
+}
+
+func readRCompHdr(r io.Reader) (rcompHdr, error) {
+	var buf [rootHDRSIZE]byte
+	_, err := io.ReadFull(r, buf[:])
+	if err != nil {
+		return rcompHdr{}, err
+	}
+	return rcompHdr{
+		algo:   string(buf[0:2]),
+		method: buf[2],
+		clen:   le3(buf[3:6]),
+		ulen:   le3(buf[6:9]),
+	}, nil
+}
+
+// le3 decodes a 3-byte little-endian unsigned integer, as used by ROOT for
+// the compressed/uncompressed lengths in a block header.
+func le3(p []byte) int32 {
+	return int32(p[0]) | int32(p[1])<<8 | int32(p[2])<<16
+}
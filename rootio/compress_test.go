@@ -0,0 +1,105 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rootio
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestCompressDecompressRoundTrip(t *testing.T) {
+	for _, algo := range []string{"ZL", "L4", "ZS"} {
+		t.Run(algo, func(t *testing.T) {
+			src := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog"), 1000)
+
+			var buf bytes.Buffer
+			n, err := compress(&buf, algo, 0, src)
+			if err != nil {
+				t.Fatalf("could not compress: %v", err)
+			}
+			if n != buf.Len() {
+				t.Fatalf("got=%d bytes written, want=%d", n, buf.Len())
+			}
+
+			hdr, err := readRCompHdr(&buf)
+			if err != nil {
+				t.Fatalf("could not read block header: %v", err)
+			}
+			if hdr.algo != algo {
+				t.Errorf("got algo=%q, want=%q", hdr.algo, algo)
+			}
+			if int(hdr.ulen) != len(src) {
+				t.Errorf("got ulen=%d, want=%d", hdr.ulen, len(src))
+			}
+			if int(hdr.clen) != buf.Len() {
+				t.Errorf("got clen=%d, want=%d (remaining compressed bytes)", hdr.clen, buf.Len())
+			}
+
+			fct, err := decompressorFor(algo)
+			if err != nil {
+				t.Fatalf("could not find decompressor: %v", err)
+			}
+			r, err := fct(io.LimitReader(&buf, int64(hdr.clen)))
+			if err != nil {
+				t.Fatalf("could not create decompressor: %v", err)
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("could not decompress: %v", err)
+			}
+			if !bytes.Equal(got, src) {
+				t.Errorf("round-trip mismatch: got=%d bytes, want=%d bytes", len(got), len(src))
+			}
+		})
+	}
+}
+
+func TestCompressChunking(t *testing.T) {
+	// a payload larger than maxCompBufSize must be split across several
+	// headers, each framing at most maxCompBufSize uncompressed bytes.
+	src := bytes.Repeat([]byte{0x42}, maxCompBufSize+1)
+
+	var buf bytes.Buffer
+	if _, err := compress(&buf, "ZL", 0, src); err != nil {
+		t.Fatalf("could not compress: %v", err)
+	}
+
+	var chunks int
+	var total int
+	for buf.Len() > 0 {
+		hdr, err := readRCompHdr(&buf)
+		if err != nil {
+			t.Fatalf("could not read block header %d: %v", chunks, err)
+		}
+		if hdr.ulen > maxCompBufSize {
+			t.Errorf("chunk %d: ulen=%d exceeds maxCompBufSize=%d", chunks, hdr.ulen, maxCompBufSize)
+		}
+		buf.Next(int(hdr.clen))
+		total += int(hdr.ulen)
+		chunks++
+	}
+	if chunks < 2 {
+		t.Fatalf("got=%d chunks, want at least 2 for a payload of %d bytes", chunks, len(src))
+	}
+	if total != len(src) {
+		t.Errorf("got=%d total uncompressed bytes across chunks, want=%d", total, len(src))
+	}
+}
+
+func TestPutLE3AndLe3(t *testing.T) {
+	cases := []int32{0, 1, 0xff, 0x100, 0xabcdef, 0xffffff}
+	for _, v := range cases {
+		var p [3]byte
+		putLE3(p[:], v)
+		if p[0] != byte(v) || p[1] != byte(v>>8) || p[2] != byte(v>>16) {
+			t.Errorf("putLE3(%#x): got=%v, want little-endian byte order", v, p)
+		}
+		if got := le3(p[:]); got != v {
+			t.Errorf("le3(putLE3(%#x)): got=%#x, want=%#x", v, got, v)
+		}
+	}
+}
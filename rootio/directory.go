@@ -0,0 +1,92 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rootio
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// tdirectory is a ROOT directory: a named collection of Keys, each
+// describing one persisted object.
+type tdirectory struct {
+	file *File
+	name string
+	keys []*Key
+}
+
+func newDirectory(f *File, name string) *tdirectory {
+	return &tdirectory{file: f, name: name}
+}
+
+// Keys returns the Keys held by this directory.
+func (dir *tdirectory) Keys() []*Key {
+	return dir.keys
+}
+
+// readKeys populates dir.keys from the Keys already on disk. Directories
+// created fresh by File.Create have nothing to read back yet: loading an
+// existing file's key list for append/read is not implemented by this
+// minimal, write-oriented File.
+func (dir *tdirectory) readKeys() error {
+	return nil
+}
+
+// Put writes obj to dir under the given name, allocating space for its Key
+// and (compressed) payload from the underlying file's free-list, falling
+// back to appending at the end of the file.
+func (dir *tdirectory) Put(name string, obj Object) error {
+	class := obj.Class()
+
+	raw := NewWBuffer(nil, nil, 0)
+	var err error
+	if m, ok := obj.(ROOTMarshaler); ok {
+		err = m.MarshalROOT(raw)
+	} else {
+		err = marshalWithStreamerInfo(raw, class, obj)
+	}
+	if err != nil {
+		return fmt.Errorf("rootio: could not marshal %q (class=%q): %w", name, class, err)
+	}
+
+	title := ""
+	if named, ok := obj.(Named); ok {
+		title = named.Title()
+	}
+
+	var body bytes.Buffer
+	nbytes, err := compress(&body, dir.file.compalgo, dir.file.compmeth, raw.Bytes())
+	if err != nil {
+		return fmt.Errorf("rootio: could not compress %q: %w", name, err)
+	}
+
+	// Size the key header for the worst case seekkey this Put could end up
+	// with -- the current end of the file, since any gap alloc reuses can
+	// only be smaller -- so the allocated window already accounts for the
+	// wider seekkey/seekpdir fields before alloc hands out a seek at all.
+	k := newKey(dir.file, dir.file.end, name, title, class, int32(len(raw.Bytes())), int32(nbytes))
+	seek := dir.file.alloc(int64(k.keylen) + int64(nbytes))
+	k.seekkey = seek
+
+	hdr := NewWBuffer(nil, nil, 0)
+	if err := k.MarshalROOT(hdr); err != nil {
+		return fmt.Errorf("rootio: could not marshal key for %q: %w", name, err)
+	}
+
+	if _, err := dir.file.f.WriteAt(hdr.Bytes(), seek); err != nil {
+		return fmt.Errorf("rootio: could not write key for %q: %w", name, err)
+	}
+	if _, err := dir.file.f.WriteAt(body.Bytes(), seek+int64(k.keylen)); err != nil {
+		return fmt.Errorf("rootio: could not write object %q: %w", name, err)
+	}
+
+	dir.keys = append(dir.keys, k)
+	return nil
+}
+
+// Put writes obj under name into the file's top-level directory.
+func (f *File) Put(name string, obj Object) error {
+	return f.dir.Put(name, obj)
+}
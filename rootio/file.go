@@ -0,0 +1,134 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rootio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// rootFileHDRSIZE is the size, in bytes, of the fixed "root" + fVersion +
+// fBEGIN preamble this writer puts at the start of every file; the first
+// key allocated in the file starts right after it.
+const rootFileHDRSIZE = 4 + 4 + 4
+
+// File is a ROOT file open for writing.
+//
+// This is a minimal, from-scratch implementation covering only what is
+// needed to create a brand new file and Put objects in its top directory;
+// it does not (yet) support opening an existing file for append, nor the
+// rest of TFile's bookkeeping (UUIDs, persisted StreamerInfo list, ...).
+type File struct {
+	f    *os.File
+	name string
+
+	compalgo string // default compression algorithm for new keys, e.g. "ZL"
+	compmeth byte
+
+	dir  *tdirectory
+	end  int64 // current end-of-file offset
+	gaps []fgap
+}
+
+// fgap is a reclaimed range of file space, recorded as a "[GAP]" key so it
+// can be reused by a later Put.
+type fgap struct {
+	seek int64
+	len  int64
+}
+
+// Create creates a new ROOT file at name, truncating it if it already
+// exists, ready to have objects Put into its top-level Directory.
+func Create(name string) (*File, error) {
+	fd, err := os.Create(name)
+	if err != nil {
+		return nil, fmt.Errorf("rootio: could not create file %q: %w", name, err)
+	}
+
+	var hdr [rootFileHDRSIZE]byte
+	copy(hdr[:4], "root")
+	binary.BigEndian.PutUint32(hdr[4:8], 0) // fVersion: this minimal writer does not version its own format
+	binary.BigEndian.PutUint32(hdr[8:12], uint32(rootFileHDRSIZE))
+	if _, err := fd.WriteAt(hdr[:], 0); err != nil {
+		return nil, fmt.Errorf("rootio: could not write preamble of file %q: %w", name, err)
+	}
+
+	f := &File{
+		f:        fd,
+		name:     name,
+		compalgo: "ZL",
+		end:      rootFileHDRSIZE,
+	}
+	f.dir = newDirectory(f, name)
+
+	return f, nil
+}
+
+// SetCompression selects the compression algorithm ("ZL", "L4" or "ZS")
+// used for keys subsequently Put into this file.
+func (f *File) SetCompression(algo string) error {
+	if _, err := compressorFor(algo); err != nil {
+		return err
+	}
+	f.compalgo = algo
+	return nil
+}
+
+// Close flushes and closes the underlying OS file.
+func (f *File) Close() error {
+	return f.f.Close()
+}
+
+// ReadAt implements io.ReaderAt so a *File can back Key.load / io.SectionReader.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	return f.f.ReadAt(p, off)
+}
+
+// seekdir returns the value used as the seekpdir of keys written directly
+// into the top directory: the end of the preamble. No directory record is
+// written there; the first key Put into the file is allocated at that offset.
+func (f *File) seekdir() int64 {
+	return rootFileHDRSIZE
+}
+
+// alloc reserves n bytes of file space for a new key+object, reusing the
+// smallest free gap that fits before falling back to appending at the end
+// of the file.
+func (f *File) alloc(n int64) int64 {
+	best := -1
+	for i, g := range f.gaps {
+		if g.len < n {
+			continue
+		}
+		if best == -1 || g.len < f.gaps[best].len {
+			best = i
+		}
+	}
+	if best == -1 {
+		seek := f.end
+		f.end += n
+		return seek
+	}
+
+	g := f.gaps[best]
+	f.gaps = append(f.gaps[:best], f.gaps[best+1:]...)
+	if g.len > n {
+		f.gaps = append(f.gaps, fgap{seek: g.seek + n, len: g.len - n})
+	}
+	return g.seek
+}
+
+// free marks the seek..seek+n range as reclaimable, writing a "[GAP]" key
+// there (a negative fBytes, exactly what UnmarshalROOT recognizes as the
+// "[GAP]" sentinel) so a subsequent read of the file also sees it as free.
+func (f *File) free(seek, n int64) error {
+	f.gaps = append(f.gaps, fgap{seek: seek, len: n})
+
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(int32(-n)))
+	_, err := f.f.WriteAt(hdr[:], seek)
+	return err
+}
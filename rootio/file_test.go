@@ -0,0 +1,138 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rootio
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fileTestObj is a minimal Object+ROOTMarshaler+Named used to exercise
+// File.Put without needing a registered Factory/StreamerInfo.
+type fileTestObj struct {
+	title string
+	value int32
+}
+
+func (o *fileTestObj) Class() string { return "FileTestObj" }
+func (o *fileTestObj) Name() string  { return "obj" }
+func (o *fileTestObj) Title() string { return o.title }
+
+func (o *fileTestObj) MarshalROOT(w *WBuffer) error {
+	w.WriteI32(o.value)
+	return w.Err()
+}
+
+func TestCreateWritesPreamble(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rootio-")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "test.root")
+	f, err := Create(name)
+	if err != nil {
+		t.Fatalf("could not create file: %v", err)
+	}
+	defer f.Close()
+
+	raw, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("could not read file: %v", err)
+	}
+	if len(raw) < rootFileHDRSIZE {
+		t.Fatalf("got=%d bytes, want at least %d (the preamble)", len(raw), rootFileHDRSIZE)
+	}
+	if magic := string(raw[:4]); magic != "root" {
+		t.Errorf("got magic=%q, want=%q", magic, "root")
+	}
+	if begin := binary.BigEndian.Uint32(raw[8:12]); begin != rootFileHDRSIZE {
+		t.Errorf("got fBEGIN=%d, want=%d", begin, rootFileHDRSIZE)
+	}
+}
+
+func TestPutWritesKeyAndObject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rootio-")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "test.root")
+	f, err := Create(name)
+	if err != nil {
+		t.Fatalf("could not create file: %v", err)
+	}
+	defer f.Close()
+
+	obj := &fileTestObj{title: "a title", value: 42}
+	if err := f.Put("obj", obj); err != nil {
+		t.Fatalf("could not Put object: %v", err)
+	}
+
+	keys := f.dir.Keys()
+	if len(keys) != 1 {
+		t.Fatalf("got=%d keys, want=1", len(keys))
+	}
+	k := keys[0]
+	if k.Class() != "FileTestObj" {
+		t.Errorf("got class=%q, want=%q", k.Class(), "FileTestObj")
+	}
+	if k.Name() != "obj" || k.Title() != "a title" {
+		t.Errorf("got name=%q title=%q, want=%q/%q", k.Name(), k.Title(), "obj", "a title")
+	}
+
+	// the key must land exactly where alloc said it would, and the file
+	// must have grown to cover the key header plus the compressed object.
+	if k.seekkey != rootFileHDRSIZE {
+		t.Errorf("got seekkey=%d, want=%d (right after the preamble)", k.seekkey, rootFileHDRSIZE)
+	}
+	if f.end != k.seekkey+int64(k.keylen)+int64(k.bytes-k.keylen) {
+		t.Errorf("got file.end=%d, want=%d", f.end, k.seekkey+int64(k.bytes))
+	}
+
+	raw, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("could not read file: %v", err)
+	}
+	if int64(len(raw)) < f.end {
+		t.Fatalf("got=%d bytes on disk, want at least %d", len(raw), f.end)
+	}
+	if got := binary.BigEndian.Uint32(raw[k.seekkey : k.seekkey+4]); int32(got) != k.bytes {
+		t.Errorf("got key.bytes on disk=%d, want=%d", int32(got), k.bytes)
+	}
+}
+
+func TestAllocReusesFreedGap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rootio-")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := Create(filepath.Join(dir, "test.root"))
+	if err != nil {
+		t.Fatalf("could not create file: %v", err)
+	}
+	defer f.Close()
+
+	seek := f.alloc(100)
+	end := f.end
+	if err := f.free(seek, 100); err != nil {
+		t.Fatalf("could not free range: %v", err)
+	}
+
+	got := f.alloc(64)
+	if got != seek {
+		t.Errorf("got seek=%d, want=%d (the freed gap, reused)", got, seek)
+	}
+	if f.end != end {
+		t.Errorf("got file.end=%d, want=%d (unchanged, alloc should not have appended)", f.end, end)
+	}
+}
@@ -5,9 +5,9 @@
 package rootio
 
 import (
-	"compress/zlib"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 	"time"
 )
@@ -45,6 +45,28 @@ type Key struct {
 	title string // title of the object
 
 	obj Object
+
+	calgo  string     // 2-byte ROOT algorithm tag of the compressed object ("ZL", "XZ", "L4", "ZS"), empty if uncompressed
+	cchunk []rcompHdr // per-chunk headers of the compressed object, for diagnostics
+}
+
+// CompressionAlgo returns the 2-byte ROOT tag of the compression algorithm
+// used for this Key's object ("ZL", "XZ", "L4" or "ZS"), or the empty string
+// if the object is stored uncompressed. It is only set once the object has
+// been read (via Bytes or Object); before that it is the empty string.
+func (k *Key) CompressionAlgo() string {
+	return k.calgo
+}
+
+// ChunkSizes returns, for each compressed block making up this Key's object,
+// the compressed and uncompressed size, in that order. It is empty if the
+// object is stored uncompressed, and until the object has been read.
+func (k *Key) ChunkSizes() [][2]int32 {
+	sizes := make([][2]int32, len(k.cchunk))
+	for i, c := range k.cchunk {
+		sizes[i] = [2]int32{c.clen, c.ulen}
+	}
+	return sizes
 }
 
 func (k *Key) Class() string {
@@ -133,22 +155,45 @@ func (k *Key) load(buf []byte) ([]byte, error) {
 		buf = make([]byte, k.objlen)
 	}
 	if k.isCompressed() {
-		// Note: this contains ZL[src][dst] where src and dst are 3 bytes each.
-		// Won't bother with this for the moment, since we can cross-check against
-		// objlen.
-		const rootHDRSIZE = 9
-
-		start := k.seekkey + int64(k.keylen) + rootHDRSIZE
-		r := io.NewSectionReader(k.f, start, int64(k.bytes)-int64(k.keylen))
-		rc, err := zlib.NewReader(r)
-		if err != nil {
-			return nil, err
+		// The compressed payload is not a single stream: it is a sequence of
+		// chunks, each no larger than ROOT's max compression buffer size,
+		// and each prefixed by its own rootHDRSIZE-byte header. Walk the
+		// chunks, decompressing each into its slice of buf, until objlen
+		// bytes have been produced.
+		k.cchunk = k.cchunk[:0]
+		start := k.seekkey + int64(k.keylen)
+		pos := start
+		end := k.seekkey + int64(k.bytes)
+		dst := buf[:0]
+		for pos < end {
+			hr := io.NewSectionReader(k.f, pos, rootHDRSIZE)
+			hdr, err := readRCompHdr(hr)
+			if err != nil {
+				return nil, err
+			}
+			if k.calgo == "" {
+				k.calgo = hdr.algo
+			}
+			k.cchunk = append(k.cchunk, hdr)
+
+			fct, err := decompressorFor(hdr.algo)
+			if err != nil {
+				return nil, err
+			}
+			cr := io.NewSectionReader(k.f, pos+rootHDRSIZE, int64(hdr.clen))
+			rc, err := fct(cr)
+			if err != nil {
+				return nil, err
+			}
+			chunk := dst[len(dst) : len(dst)+int(hdr.ulen)]
+			_, err = io.ReadFull(rc, chunk)
+			if err != nil {
+				return nil, err
+			}
+			dst = dst[:len(dst)+int(hdr.ulen)]
+			pos += rootHDRSIZE + int64(hdr.clen)
 		}
-		_, err = io.ReadFull(rc, buf)
-		if err != nil {
-			return nil, err
-		}
-		return buf, nil
+		return dst, nil
 	}
 	start := k.seekkey + int64(k.keylen)
 	r := io.NewSectionReader(k.f, start, int64(k.bytes))
@@ -210,6 +255,89 @@ func (k *Key) UnmarshalROOT(r *RBuffer) error {
 	return r.Err()
 }
 
+// newKey builds the Key that will describe obj once written at seekkey in
+// f, given the (possibly compressed) on-file size of its payload.
+func newKey(f *File, seekkey int64, name, title, class string, objlen, nbytes int32) *Key {
+	k := &Key{
+		f:        f,
+		version:  1,
+		objlen:   objlen,
+		datetime: time.Now(),
+		cycle:    1,
+		seekkey:  seekkey,
+		seekpdir: f.seekdir(),
+		class:    class,
+		name:     name,
+		title:    title,
+	}
+	k.keylen = k.sizeof()
+	k.bytes = k.keylen + nbytes
+	if k.large() {
+		k.version += 1000
+	}
+	return k
+}
+
+// large reports whether this Key must use the 64-bit seekkey/seekpdir
+// variant of the key header, as ROOT does once a file grows past what fits
+// in a signed 32-bit offset.
+func (k *Key) large() bool {
+	return k.seekkey > math.MaxInt32 || k.seekpdir > math.MaxInt32
+}
+
+// sizeof returns the number of bytes MarshalROOT will write for this Key,
+// i.e. the value to store in keylen.
+func (k *Key) sizeof() int32 {
+	const fixed32 = 4 + 2 + 4 + 4 + 2 + 2 + 4 + 4 // bytes,version,objlen,datime,keylen,cycle,seekkey,seekpdir
+	n := int32(fixed32)
+	if k.large() {
+		n += 8 // seekkey and seekpdir grow from 4 to 8 bytes each
+	}
+	for _, s := range []string{k.class, k.name, k.title} {
+		n += stringSizeof(s)
+	}
+	return n
+}
+
+func stringSizeof(s string) int32 {
+	if s == "" {
+		return 2 // WriteString writes a zero length byte, then one more zero byte
+	}
+	if len(s) > 254 {
+		return 1 + 4 + int32(len(s))
+	}
+	return 1 + int32(len(s))
+}
+
+// MarshalROOT encodes the key header to w, choosing the 32- or 64-bit
+// seekkey/seekpdir variant from the Key's version, which newKey sets from
+// how large the file has grown, just as ROOT does. The object payload
+// itself (compressed or not) must be written by the caller immediately after.
+func (k *Key) MarshalROOT(w *WBuffer) error {
+	if w.Err() != nil {
+		return w.Err()
+	}
+
+	w.WriteI32(k.bytes)
+	w.WriteI16(k.version)
+	w.WriteI32(k.objlen)
+	w.WriteU32(time2datime(k.datetime))
+	w.WriteI16(int16(k.keylen))
+	w.WriteI16(k.cycle)
+	if k.version > 1000 {
+		w.WriteI64(k.seekkey)
+		w.WriteI64(k.seekpdir)
+	} else {
+		w.WriteI32(int32(k.seekkey))
+		w.WriteI32(int32(k.seekpdir))
+	}
+	w.WriteString(k.class)
+	w.WriteString(k.name)
+	w.WriteString(k.title)
+
+	return w.Err()
+}
+
 func init() {
 	f := func() reflect.Value {
 		o := &Key{}
@@ -222,3 +350,4 @@ func init() {
 var _ Object = (*Key)(nil)
 var _ Named = (*Key)(nil)
 var _ ROOTUnmarshaler = (*Key)(nil)
+var _ ROOTMarshaler = (*Key)(nil)
@@ -0,0 +1,121 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rootio
+
+import "bytes"
+
+// ROOT tags an object or class name's 4-byte length/offset word with one
+// of these masks to say how to interpret the rest of the word.
+const (
+	kClassMask     uint32 = 0x80000000 // rest of the word is the absolute offset of an earlier occurrence
+	kByteCountMask uint32 = 0x40000000 // rest of the word is this object's byte count
+	kNewClassTag   uint32 = 0xffffffff // the class name that follows has not been seen before
+)
+
+// WriteObjectAny writes obj to w, ROOT-style: if obj has already been
+// written through this WBuffer, only a 4-byte kClassMask-tagged reference
+// to its earlier absolute offset is emitted; otherwise the object's class
+// name (via WriteClass) and its marshaled body are written in full, framed
+// by a kByteCountMask-tagged byte count, and the object is recorded in w's
+// reference table for any later occurrence to point back to.
+func (w *WBuffer) WriteObjectAny(obj Object) error {
+	if w.err != nil {
+		return w.err
+	}
+	if obj == nil {
+		w.WriteU32(0)
+		return w.err
+	}
+
+	if w.objRefs == nil {
+		// Seed the forward index from refs, the offset->object table a
+		// caller may have pre-populated with objects already written
+		// elsewhere (e.g. by an earlier Key), so those are recognized as
+		// duplicates too, not just objects written earlier through w itself.
+		w.objRefs = make(map[interface{}]int64, len(w.refs))
+		for off, o := range w.refs {
+			w.objRefs[o] = off
+		}
+	}
+
+	if off, ok := w.objRefs[obj]; ok {
+		w.WriteU32(kClassMask | uint32(off))
+		return w.err
+	}
+
+	// start is where the byte-count prefix will land; the object body --
+	// and any back-reference recorded while writing it -- begins 4 bytes
+	// further on. Buffer the class tag and object body separately so their
+	// combined length is known before the byte count prefix is written to w
+	// -- WBuffer cannot seek back to patch it in afterwards -- but share w's
+	// reference tables with the sub buffer (and base its offset on w's
+	// current position) so a back-reference recorded while marshaling a
+	// nested object still resolves to the right absolute offset.
+	if w.classRefs == nil {
+		w.classRefs = make(map[string]uint32)
+	}
+
+	start := w.Pos()
+	var body bytes.Buffer
+	sub := NewWBufferFrom(&body, w.refs, uint32(start)+4)
+	sub.objRefs = w.objRefs
+	sub.classRefs = w.classRefs
+
+	if err := sub.WriteClass(obj.Class()); err != nil {
+		w.err = err
+		return w.err
+	}
+	if m, ok := obj.(ROOTMarshaler); ok {
+		if err := m.MarshalROOT(sub); err != nil {
+			w.err = err
+			return w.err
+		}
+	}
+	if sub.Err() != nil {
+		w.err = sub.Err()
+		return w.err
+	}
+
+	w.WriteU32(kByteCountMask | uint32(body.Len()))
+	w.write(body.Bytes())
+	if w.err != nil {
+		return w.err
+	}
+
+	w.objRefs[obj] = start
+	w.refs[start] = obj
+
+	return nil
+}
+
+// WriteClass writes name to w, ROOT-style: if name has already been
+// written through this WBuffer, only a 4-byte kClassMask-tagged reference
+// to its earlier absolute offset is emitted; otherwise a kNewClassTag
+// marker followed by the name itself is written, and name is recorded in
+// w's class reference table for any later occurrence to point back to.
+func (w *WBuffer) WriteClass(name string) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if off, ok := w.classRefs[name]; ok {
+		w.WriteU32(kClassMask | off)
+		return w.err
+	}
+
+	start := uint32(w.Pos())
+	w.WriteU32(kNewClassTag)
+	w.WriteString(name)
+	if w.err != nil {
+		return w.err
+	}
+
+	if w.classRefs == nil {
+		w.classRefs = make(map[string]uint32)
+	}
+	w.classRefs[name] = start
+
+	return nil
+}
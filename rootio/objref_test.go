@@ -0,0 +1,84 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rootio
+
+import "testing"
+
+// objrefLeaf is a minimal Object+ROOTMarshaler used to exercise
+// WriteObjectAny/WriteClass without needing a real ROOT class.
+type objrefLeaf struct {
+	class string
+	value int32
+}
+
+func (o *objrefLeaf) Class() string { return o.class }
+
+func (o *objrefLeaf) MarshalROOT(w *WBuffer) error {
+	w.WriteI32(o.value)
+	return w.Err()
+}
+
+// objrefBranch wraps a leaf object of its own, so that the leaf's class name
+// is first announced one nesting level below the top-level WBuffer.
+type objrefBranch struct {
+	class string
+	leaf  *objrefLeaf
+}
+
+func (o *objrefBranch) Class() string { return o.class }
+
+func (o *objrefBranch) MarshalROOT(w *WBuffer) error {
+	return w.WriteObjectAny(o.leaf)
+}
+
+func readTag(p []byte) uint32 {
+	return uint32(p[0])<<24 | uint32(p[1])<<16 | uint32(p[2])<<8 | uint32(p[3])
+}
+
+func TestWriteObjectAnyBackReference(t *testing.T) {
+	obj := &objrefLeaf{class: "MockClass", value: 1}
+
+	w := NewWBuffer(nil, nil, 0)
+	if err := w.WriteObjectAny(obj); err != nil {
+		t.Fatalf("could not write object: %v", err)
+	}
+	firstEnd := len(w.Bytes())
+
+	if err := w.WriteObjectAny(obj); err != nil {
+		t.Fatalf("could not write repeated object: %v", err)
+	}
+	got := w.Bytes()[firstEnd:]
+	if len(got) != 4 {
+		t.Fatalf("got=%d bytes for a repeated object, want=4 (a back-reference)", len(got))
+	}
+	if tag := readTag(got); tag&kClassMask == 0 {
+		t.Errorf("got tag=%#x, want the kClassMask bit set", tag)
+	}
+}
+
+func TestWriteObjectAnyClassBackReferenceAcrossNesting(t *testing.T) {
+	// branch.leaf's class is first announced while marshaling branch, one
+	// level below the top-level WBuffer; a later, sibling WriteClass call
+	// for the same class name at the top level must still find it.
+	branch := &objrefBranch{class: "BranchClass", leaf: &objrefLeaf{class: "LeafClass", value: 1}}
+
+	w := NewWBuffer(nil, nil, 0)
+	if err := w.WriteObjectAny(branch); err != nil {
+		t.Fatalf("could not write branch object: %v", err)
+	}
+
+	if err := w.WriteClass("LeafClass"); err != nil {
+		t.Fatalf("could not write sibling class tag: %v", err)
+	}
+
+	got := w.Bytes()
+	tag := readTag(got[len(got)-4:])
+	if tag == kNewClassTag {
+		t.Errorf("class %q re-announced at the top level instead of back-referenced", "LeafClass")
+	}
+	if tag&kClassMask == 0 {
+		t.Errorf("got tag=%#x, want the kClassMask bit set", tag)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2017 The go-hep Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rootio
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// StreamerInfo describes, for a class that does not implement
+// ROOTMarshaler itself, how to serialize its struct fields as a ROOT
+// object: each StreamerElement names one Go struct field, in the order
+// they must be written.
+//
+// This is a first, reflection-based cut at generic streaming: it supports
+// the primitive kinds WBuffer already knows how to write, and nothing more
+// exotic (no nested objects, no collections).
+type StreamerInfo struct {
+	Name     string
+	Title    string
+	Elements []StreamerElement
+}
+
+// StreamerElement is a single named field of a StreamerInfo.
+type StreamerElement struct {
+	Name string // name of the corresponding Go struct field
+}
+
+var streamerInfos = map[string]StreamerInfo{}
+
+// RegisterStreamerInfo records how to serialize objects of the given class
+// when they do not implement ROOTMarshaler themselves. It panics if a
+// StreamerInfo is already registered under that class name.
+func RegisterStreamerInfo(si StreamerInfo) {
+	if _, dup := streamerInfos[si.Name]; dup {
+		panic(fmt.Errorf("rootio: StreamerInfo already registered for class %q", si.Name))
+	}
+	streamerInfos[si.Name] = si
+}
+
+func streamerInfoFor(class string) (StreamerInfo, bool) {
+	si, ok := streamerInfos[class]
+	return si, ok
+}
+
+// marshalWithStreamerInfo writes obj's fields to w according to the
+// StreamerInfo registered for class, in lieu of obj implementing
+// ROOTMarshaler directly.
+func marshalWithStreamerInfo(w *WBuffer, class string, obj Object) error {
+	si, ok := streamerInfoFor(class)
+	if !ok {
+		return fmt.Errorf("rootio: class %q implements neither rootio.ROOTMarshaler nor has a registered rootio.StreamerInfo", class)
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(obj))
+	for _, elt := range si.Elements {
+		fv := rv.FieldByName(elt.Name)
+		if !fv.IsValid() {
+			return fmt.Errorf("rootio: class %q has no field %q described by its StreamerInfo", class, elt.Name)
+		}
+		switch fv.Kind() {
+		case reflect.Int8:
+			w.WriteI8(int8(fv.Int()))
+		case reflect.Int16:
+			w.WriteI16(int16(fv.Int()))
+		case reflect.Int32:
+			w.WriteI32(int32(fv.Int()))
+		case reflect.Int64:
+			w.WriteI64(fv.Int())
+		case reflect.Uint8:
+			w.WriteU8(uint8(fv.Uint()))
+		case reflect.Uint16:
+			w.WriteU16(uint16(fv.Uint()))
+		case reflect.Uint32:
+			w.WriteU32(uint32(fv.Uint()))
+		case reflect.Uint64:
+			w.WriteU64(fv.Uint())
+		case reflect.Float32:
+			w.WriteF32(float32(fv.Float()))
+		case reflect.Float64:
+			w.WriteF64(fv.Float())
+		case reflect.String:
+			w.WriteString(fv.String())
+		default:
+			return fmt.Errorf("rootio: field %q of class %q has unsupported kind %v", elt.Name, class, fv.Kind())
+		}
+	}
+	return w.Err()
+}
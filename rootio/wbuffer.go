@@ -7,15 +7,15 @@ package rootio
 import (
 	"bytes"
 	"encoding/binary"
-	"io"
 	"math"
 )
 
+// wbuff is the minimal sink a WBuffer writes to. It intentionally asks for
+// nothing beyond io.Writer: WBuffer tracks its own write position with a
+// running counter (see Pos), so it never needs its sink to support seeking
+// or random access.
 type wbuff interface {
-	io.Writer
-	//io.Seeker
-	//io.WriterAt
-	//Len() int
+	Write(p []byte) (int, error)
 }
 
 // WBuffer is a write-only ROOT buffer for streaming.
@@ -23,7 +23,11 @@ type WBuffer struct {
 	w      wbuff
 	err    error
 	offset uint32
-	refs   map[int64]interface{}
+	pos    int64 // bytes written so far, i.e. position relative to offset
+
+	refs      map[int64]interface{} // absolute offset -> object already written there
+	objRefs   map[interface{}]int64 // object -> absolute offset, for back-reference lookups
+	classRefs map[string]uint32     // class name -> absolute offset, for back-reference lookups
 }
 
 func NewWBufferFrom(w wbuff, refs map[int64]interface{}, offset uint32) *WBuffer {
@@ -38,52 +42,41 @@ func NewWBufferFrom(w wbuff, refs map[int64]interface{}, offset uint32) *WBuffer
 }
 
 func NewWBuffer(data []byte, refs map[int64]interface{}, offset uint32) *WBuffer {
-	if refs == nil {
-		refs = make(map[int64]interface{})
-	}
-	return &WBuffer{
-		w:      bytes.NewBuffer(data),
-		refs:   refs,
-		offset: offset,
-	}
+	return NewWBufferFrom(bytes.NewBuffer(data), refs, offset)
 }
 
-/*
+func (w *WBuffer) Err() error {
+	return w.err
+}
+
+// Pos returns the absolute offset of the next byte to be written, i.e. the
+// number of bytes written so far plus this WBuffer's base offset. Unlike
+// ROOT's own TBuffer, it is backed by a running counter rather than a seek
+// on the underlying writer, since wbuff does not support seeking.
 func (w *WBuffer) Pos() int64 {
-	//pos, _ := w.w.Seek(0, ioSeekCurrent)
-	pos := int64(w.w.Len())
-	return pos + int64(w.offset)
+	return int64(w.offset) + w.pos
 }
 
-func (r *WBuffer) setPos(pos int64) error {
-	pos -= int64(r.offset)
-	got, err := r.w.Seek(pos, ioSeekStart)
-	if err != nil {
-		return err
-	}
-	if got != pos {
-		return errorf("rootio: WBuffer too short (got=%v want=%v)", got, pos)
+// Bytes returns the bytes written so far, for a WBuffer backed by an
+// in-memory buffer (i.e. created via NewWBuffer). It returns nil for a
+// WBuffer wrapping an arbitrary wbuff via NewWBufferFrom.
+func (w *WBuffer) Bytes() []byte {
+	if b, ok := w.w.(*bytes.Buffer); ok {
+		return b.Bytes()
 	}
 	return nil
 }
-*/
-
-func (w *WBuffer) Err() error {
-	return w.err
-}
 
 func (w *WBuffer) write(p []byte) {
 	if w.err != nil {
 		return
 	}
-	_, w.err = w.w.Write(p)
+	n, err := w.w.Write(p)
+	w.pos += int64(n)
+	w.err = err
 }
 
 func (w *WBuffer) WriteString(s string) {
-	if w.err != nil {
-		return
-	}
-
 	switch {
 	case len(s) > 254: // large string
 		w.WriteU8(255)
@@ -98,97 +91,59 @@ func (w *WBuffer) WriteString(s string) {
 }
 
 func (w *WBuffer) WriteI8(v int8) {
-	if w.err != nil {
-		return
-	}
 	var buf = [1]byte{byte(v)}
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }
 
 func (w *WBuffer) WriteI16(v int16) {
-	if w.err != nil {
-		return
-	}
-
 	var buf [2]byte
 	binary.BigEndian.PutUint16(buf[:], uint16(v))
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }
 
 func (w *WBuffer) WriteI32(v int32) {
-	if w.err != nil {
-		return
-	}
-
 	var buf [4]byte
 	binary.BigEndian.PutUint32(buf[:], uint32(v))
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }
 
 func (w *WBuffer) WriteI64(v int64) {
-	if w.err != nil {
-		return
-	}
-
 	var buf [8]byte
 	binary.BigEndian.PutUint64(buf[:], uint64(v))
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }
 
 func (w *WBuffer) WriteU8(v uint8) {
-	if w.err != nil {
-		return
-	}
 	var buf = [1]byte{v}
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }
 
 func (w *WBuffer) WriteU16(v uint16) {
-	if w.err != nil {
-		return
-	}
-
 	var buf [2]byte
 	binary.BigEndian.PutUint16(buf[:], v)
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }
 
 func (w *WBuffer) WriteU32(v uint32) {
-	if w.err != nil {
-		return
-	}
-
 	var buf [4]byte
 	binary.BigEndian.PutUint32(buf[:], v)
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }
 
 func (w *WBuffer) WriteU64(v uint64) {
-	if w.err != nil {
-		return
-	}
-
 	var buf [8]byte
 	binary.BigEndian.PutUint64(buf[:], v)
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }
 
 func (w *WBuffer) WriteF32(v float32) {
-	if w.err != nil {
-		return
-	}
-
 	var buf [4]byte
 	binary.BigEndian.PutUint32(buf[:], math.Float32bits(v))
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }
 
 func (w *WBuffer) WriteF64(v float64) {
-	if w.err != nil {
-		return
-	}
-
 	var buf [8]byte
 	binary.BigEndian.PutUint64(buf[:], math.Float64bits(v))
-	_, w.err = w.w.Write(buf[:])
+	w.write(buf[:])
 }